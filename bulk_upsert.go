@@ -2,10 +2,8 @@ package gormbulkups
 
 import (
 	"errors"
-	"fmt"
 	"reflect"
 	"strings"
-	"time"
 
 	"github.com/jinzhu/gorm"
 )
@@ -17,127 +15,236 @@ import (
 //                  Larger size will normally lead the better performance, but 2000 to 3000 is reasonable.
 // [excludeColumns] Columns you want to exclude from upsert. You can omit if there is no column you want to exclude.
 func BulkUpsert(db *gorm.DB, objects []interface{}, chunkSize int, excludeColumns ...string) error {
+	return bulkWrite(db, objects, chunkSize, ModeUpsert, excludeColumns...)
+}
+
+// BulkInsert inserts multiple records at once with a plain INSERT and no
+// conflict handling; a conflicting row causes the whole chunk to fail.
+// [objects]        Must be a slice of struct
+// [chunkSize]      Number of records to insert at once. See BulkUpsert.
+// [excludeColumns] Columns you want to exclude from the insert.
+func BulkInsert(db *gorm.DB, objects []interface{}, chunkSize int, excludeColumns ...string) error {
+	return bulkWrite(db, objects, chunkSize, ModeInsert, excludeColumns...)
+}
+
+// BulkInsertIgnore inserts multiple records at once, silently skipping any
+// row that conflicts with an existing one instead of failing the chunk.
+// [objects]        Must be a slice of struct
+// [chunkSize]      Number of records to insert at once. See BulkUpsert.
+// [excludeColumns] Columns you want to exclude from the insert.
+func BulkInsertIgnore(db *gorm.DB, objects []interface{}, chunkSize int, excludeColumns ...string) error {
+	return bulkWrite(db, objects, chunkSize, ModeInsertIgnore, excludeColumns...)
+}
+
+// BulkUpsertOnConflictDoNothing inserts multiple records at once, leaving
+// the existing row untouched whenever a conflict occurs rather than
+// overwriting it as BulkUpsert does.
+// [objects]        Must be a slice of struct
+// [chunkSize]      Number of records to upsert at once. See BulkUpsert.
+// [excludeColumns] Columns you want to exclude from the upsert.
+func BulkUpsertOnConflictDoNothing(db *gorm.DB, objects []interface{}, chunkSize int, excludeColumns ...string) error {
+	return bulkWrite(db, objects, chunkSize, ModeUpsertDoNothing, excludeColumns...)
+}
+
+// BulkUpsertOptions configures BulkUpsertWithOptions beyond what the
+// heuristic-based BulkUpsert infers automatically from struct tags.
+type BulkUpsertOptions struct {
+	// ConflictColumns explicitly names the columns of the unique index or
+	// primary key that incoming rows may collide with. Required on
+	// Postgres, SQLite and SQL Server whenever UpdateColumns is set or
+	// OnConflictDoNothing is true and the struct's primary key is not the
+	// conflict target -- e.g. a composite unique index. Defaults to the
+	// primary key when nil.
+	ConflictColumns []string
+	// UpdateColumns, when non-nil, is used verbatim as the SET clause
+	// instead of inferring it by excluding primary keys, unique fields and
+	// relations as BulkUpsert does.
+	UpdateColumns []string
+	// ExcludeColumns lists columns to omit from both VALUES and the
+	// inferred UpdateColumns.
+	ExcludeColumns []string
+	// OnConflictDoNothing, when true, leaves the existing row untouched on
+	// conflict instead of applying UpdateColumns.
+	OnConflictDoNothing bool
+	// ReturnColumns requests the named columns back via RETURNING. Only
+	// Postgres and SQLite support it; other dialects return an error if
+	// it is non-empty.
+	ReturnColumns []string
+	// Atomic wraps every chunk of a BulkUpsertContext call in a single
+	// transaction, with a SAVEPOINT around each chunk so a failing chunk
+	// can be rolled back on its own when ContinueOnError is true. Ignored
+	// outside BulkUpsertContext.
+	Atomic bool
+	// ContinueOnError keeps BulkUpsertContext processing the remaining
+	// chunks after one fails instead of stopping immediately; failures are
+	// collected in BulkResult.FailedChunks. Ignored outside
+	// BulkUpsertContext.
+	ContinueOnError bool
+}
+
+// BulkUpsertWithOptions upserts multiple records at once like BulkUpsert,
+// but lets the caller override the conflict target and the columns to
+// update instead of relying on struct-tag inference, and optionally
+// retrieve columns back via RETURNING.
+// [objects]   Must be a slice of struct
+// [chunkSize] Number of records to upsert at once. See BulkUpsert.
+// [opts]      See BulkUpsertOptions.
+func BulkUpsertWithOptions(db *gorm.DB, objects []interface{}, chunkSize int, opts BulkUpsertOptions) ([]map[string]interface{}, error) {
+	mode := ModeUpsert
+	if opts.OnConflictDoNothing {
+		mode = ModeUpsertDoNothing
+	}
+
+	var returned []map[string]interface{}
+	for _, objSet := range splitObjects(objects, chunkSize) {
+		_, rows, err := upsertObjSet(db, objSet, mode, opts)
+		if err != nil {
+			return returned, err
+		}
+		returned = append(returned, rows...)
+	}
+	return returned, nil
+}
+
+func bulkWrite(db *gorm.DB, objects []interface{}, chunkSize int, mode Mode, excludeColumns ...string) error {
+	opts := BulkUpsertOptions{ExcludeColumns: excludeColumns}
 	// Split records with specified size not to exceed Database parameter limit
 	for _, objSet := range splitObjects(objects, chunkSize) {
-		if err := upsertObjSet(db, objSet, excludeColumns...); err != nil {
+		if _, _, err := upsertObjSet(db, objSet, mode, opts); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func upsertObjSet(db *gorm.DB, objects []interface{}, excludeColumns ...string) error {
+func upsertObjSet(db *gorm.DB, objects []interface{}, mode Mode, opts BulkUpsertOptions) (int64, []map[string]interface{}, error) {
 	if len(objects) == 0 {
-		return nil
+		return 0, nil, nil
 	}
 
-	firstAttrs, err := extractMapValue(objects[0], excludeColumns)
-	if err != nil {
-		return err
-	}
+	dialect := dialectForDB(db)
+	excludeColumns := opts.ExcludeColumns
 
-	attrSize := len(firstAttrs)
+	if reflect.ValueOf(objects[0]).Kind() != reflect.Struct {
+		return 0, nil, errors.New("value must be kind of Struct")
+	}
+	schema := schemaFor(objects[0], excludeColumns)
+	attrSize := len(schema.fields)
 
 	// Scope to eventually run SQL
 	mainScope := db.NewScope(objects[0])
-	// Store placeholders for embedding variables
-	placeholders := make([]string, 0, attrSize)
-
-	// Replace with database column name
-	dbColumns := make([]string, 0, attrSize)
-	for _, key := range sortedKeys(firstAttrs) {
-		dbColumns = append(dbColumns, gorm.ToColumnName(key))
-	}
+	dbColumns := schema.columns
+
+	updateColumns := opts.UpdateColumns
+	if updateColumns == nil {
+		updateColumns = make([]string, 0)
+		for _, field := range mainScope.Fields() {
+			_, hasForeignKey := field.TagSettingsGet("FOREIGNKEY")
+			_, isUnique := field.TagSettingsGet("UNIQUE")
+			_, hasUniqueIndex := field.TagSettingsGet("UNIQUE_INDEX")
+			if containString(excludeColumns, field.Struct.Name) ||
+				field.StructField.Relationship != nil ||
+				hasForeignKey ||
+				field.IsIgnored ||
+				field.IsPrimaryKey ||
+				isUnique ||
+				hasUniqueIndex {
+				continue
+			}
 
-	duplicates := make([]string, 0)
-	for _, field := range mainScope.Fields() {
-		_, hasForeignKey := field.TagSettingsGet("FOREIGNKEY")
-		_, isUnique := field.TagSettingsGet("UNIQUE")
-		_, hasUniqueIndex := field.TagSettingsGet("UNIQUE_INDEX")
-		if containString(excludeColumns, field.Struct.Name) ||
-			field.StructField.Relationship != nil ||
-			hasForeignKey ||
-			field.IsIgnored ||
-			field.IsPrimaryKey ||
-			isUnique ||
-			hasUniqueIndex {
-			continue
+			updateColumns = append(updateColumns, field.DBName)
 		}
-
-		duplicates = append(duplicates, fmt.Sprintf("`%s`=VALUES(`%s`)", field.DBName, field.DBName))
 	}
 
-	for _, obj := range objects {
-		objAttrs, err := extractMapValue(obj, excludeColumns)
-		if err != nil {
-			return err
+	// Conflict target for dialects that require one explicitly (Postgres,
+	// SQLite, SQL Server); MySQL has no conflict target and only uses its
+	// first column for the no-op of ModeUpsertDoNothing. Defaults to the primary key,
+	// but callers targeting a composite unique index must set it via
+	// BulkUpsertOptions.ConflictColumns.
+	conflictColumns := opts.ConflictColumns
+	if conflictColumns == nil {
+		conflictColumns = make([]string, 0)
+		for _, field := range mainScope.PrimaryFields() {
+			conflictColumns = append(conflictColumns, field.DBName)
 		}
+	}
 
-		// If object sizes are different, SQL statement loses consistency
-		if len(objAttrs) != attrSize {
-			return errors.New("attribute sizes are inconsistent")
-		}
+	// Single pass over objects: reuse one scratch buffer for every bind
+	// variable instead of allocating a map plus a gorm.Scope per object.
+	objType := reflect.TypeOf(objects[0])
+	scratch := make([]interface{}, 0, len(objects)*attrSize)
 
-		scope := db.NewScope(obj)
+	var valuesSQL strings.Builder
+	valuesSQL.Grow(len(objects) * (attrSize*3 + 2))
 
-		// Append variables
-		variables := make([]string, 0, attrSize)
-		for _, key := range sortedKeys(objAttrs) {
-			scope.AddToVars(objAttrs[key])
-			variables = append(variables, "?")
+	varIndex := 0
+	for rowIdx, obj := range objects {
+		if reflect.TypeOf(obj) != objType {
+			return 0, nil, errors.New("gormbulkups: all objects must share the same type")
 		}
 
-		valueQuery := "(" + strings.Join(variables, ", ") + ")"
-		placeholders = append(placeholders, valueQuery)
-
-		// Also append variables to mainScope
-		mainScope.SQLVars = append(mainScope.SQLVars, scope.SQLVars...)
+		v := reflect.ValueOf(obj)
+		if rowIdx > 0 {
+			valuesSQL.WriteString(", ")
+		}
+		valuesSQL.WriteByte('(')
+		for i := range schema.fields {
+			if i > 0 {
+				valuesSQL.WriteString(", ")
+			}
+			scratch = append(scratch, schema.fields[i].extract(v))
+			valuesSQL.WriteString(dialect.Placeholder(varIndex))
+			varIndex++
+		}
+		valuesSQL.WriteByte(')')
 	}
+	mainScope.SQLVars = scratch
 
-	sql := "INSERT INTO %s (`%s`) VALUES %s"
-	args := []interface{}{
-		mainScope.QuotedTableName(),
-		strings.Join(dbColumns, "`, `"),
-		strings.Join(placeholders, ", "),
+	sql, err := dialect.BuildUpsert(mainScope.QuotedTableName(), dbColumns, []string{valuesSQL.String()}, mode, updateColumns, conflictColumns, opts.ReturnColumns)
+	if err != nil {
+		return 0, nil, err
 	}
-	if len(duplicates) > 0 {
-		sql += " ON DUPLICATE KEY UPDATE %s"
-		args = append(args, strings.Join(duplicates, ", "))
+	mainScope.Raw(sql)
+
+	if len(opts.ReturnColumns) == 0 {
+		result := db.Exec(mainScope.SQL, mainScope.SQLVars...)
+		return result.RowsAffected, nil, result.Error
 	}
-	mainScope.Raw(fmt.Sprintf(sql, args...))
 
-	return db.Exec(mainScope.SQL, mainScope.SQLVars...).Error
+	rows, err := scanReturning(db, mainScope.SQL, mainScope.SQLVars)
+	return int64(len(rows)), rows, err
 }
 
-// Obtain columns and values required for upsert from interface
-func extractMapValue(value interface{}, excludeColumns []string) (map[string]interface{}, error) {
-	if reflect.ValueOf(value).Kind() != reflect.Struct {
-		return nil, errors.New("value must be kind of Struct")
+// scanReturning executes sql and collects every returned row as a
+// column-name-keyed map, used to surface BulkUpsertOptions.ReturnColumns.
+func scanReturning(db *gorm.DB, sql string, vars []interface{}) ([]map[string]interface{}, error) {
+	rows, err := db.Raw(sql, vars...).Rows()
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	var attrs = map[string]interface{}{}
-
-	for _, field := range (&gorm.Scope{Value: value}).Fields() {
-		// Exclude relational record because it's not directly contained in database columns
-		_, hasForeignKey := field.TagSettingsGet("FOREIGNKEY")
-
-		if !containString(excludeColumns, field.Struct.Name) &&
-			field.StructField.Relationship == nil &&
-			!hasForeignKey &&
-			!field.IsIgnored {
-			if field.Struct.Name == "CreatedAt" || field.Struct.Name == "UpdatedAt" {
-				attrs[field.DBName] = time.Now()
-			} else if field.StructField.HasDefaultValue && field.IsBlank {
-				// If default value presents and field is empty, assign a default value
-				if val, ok := field.TagSettingsGet("DEFAULT"); ok {
-					attrs[field.DBName] = val
-				} else {
-					attrs[field.DBName] = field.Field.Interface()
-				}
-			} else {
-				attrs[field.DBName] = field.Field.Interface()
-			}
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []map[string]interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		scanDest := make([]interface{}, len(columns))
+		for i := range values {
+			scanDest[i] = &values[i]
+		}
+		if err := rows.Scan(scanDest...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, col := range columns {
+			row[col] = values[i]
 		}
+		results = append(results, row)
 	}
-	return attrs, nil
+	return results, rows.Err()
 }
@@ -0,0 +1,129 @@
+package gormbulkups
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+)
+
+// ChunkError associates an error with the index of the chunk that produced
+// it, so a BulkUpsertContext caller running with ContinueOnError can tell
+// which rows of objects need to be retried.
+type ChunkError struct {
+	ChunkIndex int
+	Err        error
+}
+
+func (c ChunkError) Error() string {
+	return fmt.Sprintf("gormbulkups: chunk %d: %v", c.ChunkIndex, c.Err)
+}
+
+// BulkResult reports how a BulkUpsertContext call executed.
+type BulkResult struct {
+	// RowsAffected totals rows affected across every chunk that executed
+	// successfully. With opts.Atomic, when BulkUpsertContext returns an
+	// error the transaction is not committed, so these rows are not kept
+	// even though they are counted here.
+	RowsAffected int64
+	// ChunksExecuted counts chunks that executed successfully, with the same
+	// opts.Atomic caveat as RowsAffected.
+	ChunksExecuted int
+	// FailedChunks holds one ChunkError per chunk that failed. It can only
+	// hold more than one entry when opts.ContinueOnError is true, since
+	// otherwise BulkUpsertContext returns as soon as the first chunk fails.
+	FailedChunks []ChunkError
+	// ReturnedRows holds every row reported back via opts.ReturnColumns,
+	// across every chunk that committed, same as BulkUpsertWithOptions'
+	// return value. Empty when opts.ReturnColumns is empty.
+	ReturnedRows []map[string]interface{}
+}
+
+// BulkUpsertContext is BulkUpsertWithOptions with context cancellation and
+// optional per-chunk transactional isolation via opts.Atomic /
+// opts.ContinueOnError. gorm v1 has no per-statement context plumbing, so
+// ctx cancellation is only observed between chunks, not while one is
+// in flight.
+// [ctx]       Canceling or timing out ctx stops the run before its next
+//             chunk starts.
+// [objects]   Must be a slice of struct
+// [chunkSize] Number of records to upsert at once. See BulkUpsert.
+// [opts]      See BulkUpsertOptions.
+func BulkUpsertContext(ctx context.Context, db *gorm.DB, objects []interface{}, chunkSize int, opts BulkUpsertOptions) (BulkResult, error) {
+	mode := ModeUpsert
+	if opts.OnConflictDoNothing {
+		mode = ModeUpsertDoNothing
+	}
+
+	dialect := dialectForDB(db)
+
+	execDB := db
+	var tx *gorm.DB
+	if opts.Atomic {
+		tx = db.Begin()
+		if tx.Error != nil {
+			return BulkResult{}, tx.Error
+		}
+		execDB = tx
+	}
+
+	var result BulkResult
+	for i, objSet := range splitObjects(objects, chunkSize) {
+		if err := ctx.Err(); err != nil {
+			if tx != nil {
+				tx.Rollback()
+			}
+			return result, err
+		}
+
+		save, rollback, release := dialect.Savepoint(fmt.Sprintf("gormbulkups_sp%d", i))
+		if tx != nil {
+			if err := tx.Exec(save).Error; err != nil {
+				tx.Rollback()
+				return result, err
+			}
+		}
+
+		rowsAffected, rows, err := upsertObjSet(execDB, objSet, mode, opts)
+		if err != nil {
+			if tx != nil {
+				tx.Exec(rollback)
+			}
+			if !recordChunkFailure(&result, i, err, opts.ContinueOnError) {
+				if tx != nil {
+					tx.Rollback()
+				}
+				return result, err
+			}
+			continue
+		}
+
+		if tx != nil && release != "" {
+			if err := tx.Exec(release).Error; err != nil {
+				tx.Rollback()
+				return result, err
+			}
+		}
+
+		result.RowsAffected += rowsAffected
+		result.ChunksExecuted++
+		result.ReturnedRows = append(result.ReturnedRows, rows...)
+	}
+
+	if tx != nil {
+		if err := tx.Commit().Error; err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// recordChunkFailure appends a ChunkError for chunkIndex to result and
+// reports whether the caller should keep processing the remaining chunks,
+// factored out of the loop body in BulkUpsertContext so the
+// ContinueOnError/FailedChunks bookkeeping can be tested without a *gorm.DB.
+func recordChunkFailure(result *BulkResult, chunkIndex int, err error, continueOnError bool) bool {
+	result.FailedChunks = append(result.FailedChunks, ChunkError{ChunkIndex: chunkIndex, Err: err})
+	return continueOnError
+}
@@ -0,0 +1,37 @@
+package gormbulkups
+
+import (
+	"errors"
+	"testing"
+)
+
+var errChunkFailureTest = errors.New("gormbulkups: test chunk failure")
+
+func TestRecordChunkFailureContinueOnErrorCollectsAllChunks(t *testing.T) {
+	var result BulkResult
+
+	if !recordChunkFailure(&result, 0, errChunkFailureTest, true) {
+		t.Fatal("expected ContinueOnError=true to report true")
+	}
+	if !recordChunkFailure(&result, 2, errChunkFailureTest, true) {
+		t.Fatal("expected ContinueOnError=true to report true")
+	}
+
+	if len(result.FailedChunks) != 2 {
+		t.Fatalf("got %d FailedChunks, want 2: %v", len(result.FailedChunks), result.FailedChunks)
+	}
+	if result.FailedChunks[0].ChunkIndex != 0 || result.FailedChunks[1].ChunkIndex != 2 {
+		t.Errorf("got chunk indexes %d, %d; want 0, 2", result.FailedChunks[0].ChunkIndex, result.FailedChunks[1].ChunkIndex)
+	}
+}
+
+func TestRecordChunkFailureStopsWithoutContinueOnError(t *testing.T) {
+	var result BulkResult
+
+	if recordChunkFailure(&result, 0, errChunkFailureTest, false) {
+		t.Fatal("expected ContinueOnError=false to report false")
+	}
+	if len(result.FailedChunks) != 1 {
+		t.Fatalf("got %d FailedChunks, want 1", len(result.FailedChunks))
+	}
+}
@@ -0,0 +1,74 @@
+package gormbulkups
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+// BulkUpsertMigrate additively migrates the target table to match the first
+// object's struct definition -- via db.AutoMigrate, which creates the table
+// if it doesn't exist and adds any columns present on the struct but
+// missing from the table -- then adds a unique index over
+// opts.ConflictColumns unless an index under the name conflictIndexName
+// derives already exists (an existing unique index under another name is
+// not detected), before running
+// BulkUpsertWithOptions. Useful in ETL pipelines where the target schema
+// evolves with the struct definition and a separate migration step would
+// otherwise have to be kept in sync by hand.
+// [objects]   Must be a slice of struct
+// [chunkSize] Number of records to upsert at once. See BulkUpsert.
+// [opts]      See BulkUpsertOptions.
+func BulkUpsertMigrate(db *gorm.DB, objects []interface{}, chunkSize int, opts BulkUpsertOptions) ([]map[string]interface{}, error) {
+	if len(objects) == 0 {
+		return nil, nil
+	}
+
+	tx := db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	if err := migrateSchema(tx, objects[0], opts.ConflictColumns); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return BulkUpsertWithOptions(db, objects, chunkSize, opts)
+}
+
+// migrateSchema applies the additive migrations BulkUpsertMigrate promises:
+// create table plus add missing columns (both via AutoMigrate), then add a
+// unique index for conflictColumns.
+func migrateSchema(db *gorm.DB, sample interface{}, conflictColumns []string) error {
+	if err := db.AutoMigrate(sample).Error; err != nil {
+		return err
+	}
+
+	scope := db.NewScope(sample)
+	indexName := conflictIndexName(scope.TableName(), conflictColumns)
+	if !needsConflictIndex(conflictColumns, db.Dialect().HasIndex(scope.TableName(), indexName)) {
+		return nil
+	}
+
+	return db.Model(sample).AddUniqueIndex(indexName, conflictColumns...).Error
+}
+
+// needsConflictIndex reports whether migrateSchema still needs to create the
+// unique index for conflictColumns, factored out of migrateSchema so the
+// already-exists skip can be tested without a *gorm.DB.
+func needsConflictIndex(conflictColumns []string, hasIndex bool) bool {
+	return len(conflictColumns) > 0 && !hasIndex
+}
+
+// conflictIndexName derives a deterministic unique-index name for a
+// conflict target, so repeated BulkUpsertMigrate calls recognize an index
+// they already created.
+func conflictIndexName(table string, columns []string) string {
+	return fmt.Sprintf("idx_%s_%s_conflict", table, strings.Join(columns, "_"))
+}
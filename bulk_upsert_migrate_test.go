@@ -0,0 +1,32 @@
+package gormbulkups
+
+import "testing"
+
+func TestNeedsConflictIndex(t *testing.T) {
+	cases := []struct {
+		name            string
+		conflictColumns []string
+		hasIndex        bool
+		want            bool
+	}{
+		{"no conflict columns", nil, false, false},
+		{"index already exists", []string{"id"}, true, false},
+		{"index missing", []string{"id"}, false, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := needsConflictIndex(tc.conflictColumns, tc.hasIndex); got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestConflictIndexNameIsDeterministic(t *testing.T) {
+	got := conflictIndexName("users", []string{"tenant_id", "email"})
+	want := "idx_users_tenant_id_email_conflict"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
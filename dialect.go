@@ -0,0 +1,54 @@
+package gormbulkups
+
+import "github.com/jinzhu/gorm"
+
+// Dialect abstracts the parts of upsert statement generation that differ
+// between database backends: identifier quoting, bind variable placeholders,
+// and the upsert clause itself (ON DUPLICATE KEY UPDATE vs ON CONFLICT vs
+// MERGE). Without it upsertObjSet could only ever target MySQL.
+type Dialect interface {
+	// Name returns the dialect name as reported by gorm.Dialect.GetName().
+	Name() string
+	// QuoteIdent quotes a single column name using the dialect's quoting
+	// convention.
+	QuoteIdent(name string) string
+	// Placeholder returns the bind variable placeholder for the index-th
+	// (0-based) variable in the statement.
+	Placeholder(index int) string
+	// BuildUpsert renders the full INSERT statement for the given Mode,
+	// including the dialect-specific conflict-handling clause. table is
+	// already quoted by gorm; placeholders holds the rows' "(...)" groups,
+	// joined with ", " (an element may itself hold several comma-separated
+	// groups). conflictColumns identifies the unique/primary key the insert
+	// may collide with; SQL Server requires it whenever mode is not
+	// ModeInsert, and Postgres and SQLite require it for ModeUpsertDoNothing
+	// and for ModeUpsert with updateColumns (their ModeInsertIgnore uses an
+	// untargeted ON CONFLICT DO NOTHING). returnColumns requests a RETURNING clause where the
+	// dialect supports one; dialects that don't must error if it is
+	// non-empty.
+	BuildUpsert(table string, columns []string, placeholders []string, mode Mode, updateColumns []string, conflictColumns []string, returnColumns []string) (string, error)
+	// Savepoint renders the statements BulkUpsertContext uses to isolate
+	// one chunk within a larger transaction: save creates the named
+	// savepoint, rollback reverts to it, and release discards it once the
+	// chunk succeeds. Dialects without a RELEASE-equivalent statement (SQL
+	// Server) return an empty release string; callers must skip executing
+	// it in that case rather than treating the empty string as a no-op SQL
+	// statement.
+	Savepoint(name string) (save, rollback, release string)
+}
+
+// dialectForDB picks the Dialect implementation matching db's underlying
+// database, falling back to MySQL -- the library's original and
+// best-supported backend -- when the name is unrecognized.
+func dialectForDB(db *gorm.DB) Dialect {
+	switch db.Dialect().GetName() {
+	case "postgres":
+		return &postgresDialect{}
+	case "sqlite3":
+		return &sqliteDialect{}
+	case "mssql":
+		return &sqlserverDialect{}
+	default:
+		return &mysqlDialect{}
+	}
+}
@@ -0,0 +1,81 @@
+package gormbulkups
+
+import (
+	"fmt"
+	"strings"
+)
+
+// mysqlDialect generates MySQL's INSERT [IGNORE] ... ON DUPLICATE KEY UPDATE
+// syntax, the behavior this library originally shipped with.
+type mysqlDialect struct{}
+
+func (d *mysqlDialect) Name() string { return "mysql" }
+
+func (d *mysqlDialect) QuoteIdent(name string) string {
+	return "`" + name + "`"
+}
+
+func (d *mysqlDialect) Placeholder(index int) string {
+	return "?"
+}
+
+func (d *mysqlDialect) BuildUpsert(table string, columns []string, placeholders []string, mode Mode, updateColumns []string, conflictColumns []string, returnColumns []string) (string, error) {
+	if len(returnColumns) > 0 {
+		return "", fmt.Errorf("gormbulkups: ReturnColumns is not supported on %s", d.Name())
+	}
+
+	quotedColumns := make([]string, len(columns))
+	for i, c := range columns {
+		quotedColumns[i] = d.QuoteIdent(c)
+	}
+
+	verb := "INSERT"
+	if mode == ModeInsertIgnore {
+		verb = "INSERT IGNORE"
+	}
+	sql := fmt.Sprintf("%s INTO %s (%s) VALUES %s", verb, table, strings.Join(quotedColumns, ", "), strings.Join(placeholders, ", "))
+
+	switch mode {
+	case ModeInsert, ModeInsertIgnore:
+		return sql, nil
+	case ModeUpsertDoNothing:
+		// MySQL has no DO NOTHING equivalent; the idiomatic no-op is to
+		// reassign a conflict column to itself.
+		noop := d.noopAssignment(updateColumns, conflictColumns)
+		if noop == "" {
+			return sql, nil
+		}
+		return sql + " ON DUPLICATE KEY UPDATE " + noop, nil
+	default: // ModeUpsert
+		if len(updateColumns) == 0 {
+			return sql, nil
+		}
+		sets := make([]string, len(updateColumns))
+		for i, c := range updateColumns {
+			q := d.QuoteIdent(c)
+			sets[i] = fmt.Sprintf("%s=VALUES(%s)", q, q)
+		}
+		return sql + " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", "), nil
+	}
+}
+
+func (d *mysqlDialect) Savepoint(name string) (save, rollback, release string) {
+	return fmt.Sprintf("SAVEPOINT %s", name),
+		fmt.Sprintf("ROLLBACK TO SAVEPOINT %s", name),
+		fmt.Sprintf("RELEASE SAVEPOINT %s", name)
+}
+
+// noopAssignment returns a "col=col" clause that assigns a column to its
+// own existing value, the idiom MySQL uses to emulate ON CONFLICT DO NOTHING.
+func (d *mysqlDialect) noopAssignment(updateColumns []string, conflictColumns []string) string {
+	col := ""
+	if len(conflictColumns) > 0 {
+		col = conflictColumns[0]
+	} else if len(updateColumns) > 0 {
+		col = updateColumns[0]
+	} else {
+		return ""
+	}
+	q := d.QuoteIdent(col)
+	return fmt.Sprintf("%s=%s", q, q)
+}
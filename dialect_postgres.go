@@ -0,0 +1,73 @@
+package gormbulkups
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// postgresDialect generates PostgreSQL's INSERT ... ON CONFLICT (...) DO
+// UPDATE SET / DO NOTHING syntax.
+type postgresDialect struct{}
+
+func (d *postgresDialect) Name() string { return "postgres" }
+
+func (d *postgresDialect) QuoteIdent(name string) string {
+	return `"` + name + `"`
+}
+
+func (d *postgresDialect) Placeholder(index int) string {
+	return fmt.Sprintf("$%d", index+1)
+}
+
+func (d *postgresDialect) BuildUpsert(table string, columns []string, placeholders []string, mode Mode, updateColumns []string, conflictColumns []string, returnColumns []string) (string, error) {
+	quotedColumns := make([]string, len(columns))
+	for i, c := range columns {
+		quotedColumns[i] = d.QuoteIdent(c)
+	}
+
+	sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", table, strings.Join(quotedColumns, ", "), strings.Join(placeholders, ", "))
+
+	switch mode {
+	case ModeInsert:
+	case ModeInsertIgnore:
+		sql += " ON CONFLICT DO NOTHING"
+	case ModeUpsertDoNothing:
+		if len(conflictColumns) == 0 {
+			return "", errors.New("gormbulkups: conflict columns are required to build an ON CONFLICT clause for this dialect")
+		}
+		sql += fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", strings.Join(d.quoteAll(conflictColumns), ", "))
+	default: // ModeUpsert
+		if len(updateColumns) == 0 {
+			break
+		}
+		if len(conflictColumns) == 0 {
+			return "", errors.New("gormbulkups: conflict columns are required to build an ON CONFLICT clause for this dialect")
+		}
+		sets := make([]string, len(updateColumns))
+		for i, c := range updateColumns {
+			q := d.QuoteIdent(c)
+			sets[i] = fmt.Sprintf("%s=EXCLUDED.%s", q, q)
+		}
+		sql += fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(d.quoteAll(conflictColumns), ", "), strings.Join(sets, ", "))
+	}
+
+	if len(returnColumns) > 0 {
+		sql += " RETURNING " + strings.Join(d.quoteAll(returnColumns), ", ")
+	}
+	return sql, nil
+}
+
+func (d *postgresDialect) Savepoint(name string) (save, rollback, release string) {
+	return fmt.Sprintf("SAVEPOINT %s", name),
+		fmt.Sprintf("ROLLBACK TO SAVEPOINT %s", name),
+		fmt.Sprintf("RELEASE SAVEPOINT %s", name)
+}
+
+func (d *postgresDialect) quoteAll(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = d.QuoteIdent(n)
+	}
+	return quoted
+}
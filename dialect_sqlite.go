@@ -0,0 +1,15 @@
+package gormbulkups
+
+// sqliteDialect generates SQLite's upsert syntax, which mirrors Postgres'
+// ON CONFLICT (...) DO UPDATE SET (SQLite >= 3.24.0). It embeds
+// postgresDialect and only overrides the name and placeholder style, since
+// both backends quote identifiers with double quotes.
+type sqliteDialect struct {
+	postgresDialect
+}
+
+func (d *sqliteDialect) Name() string { return "sqlite3" }
+
+func (d *sqliteDialect) Placeholder(index int) string {
+	return "?"
+}
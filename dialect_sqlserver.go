@@ -0,0 +1,79 @@
+package gormbulkups
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sqlserverDialect generates a T-SQL MERGE statement, SQL Server having no
+// INSERT ... ON DUPLICATE/CONFLICT shorthand.
+type sqlserverDialect struct{}
+
+func (d *sqlserverDialect) Name() string { return "mssql" }
+
+func (d *sqlserverDialect) QuoteIdent(name string) string {
+	return "[" + name + "]"
+}
+
+func (d *sqlserverDialect) Placeholder(index int) string {
+	return "?"
+}
+
+func (d *sqlserverDialect) BuildUpsert(table string, columns []string, placeholders []string, mode Mode, updateColumns []string, conflictColumns []string, returnColumns []string) (string, error) {
+	if len(returnColumns) > 0 {
+		return "", fmt.Errorf("gormbulkups: ReturnColumns is not supported on %s", d.Name())
+	}
+
+	quotedColumns := make([]string, len(columns))
+	for i, c := range columns {
+		quotedColumns[i] = d.QuoteIdent(c)
+	}
+	insertCols := strings.Join(quotedColumns, ", ")
+
+	insertVals := make([]string, len(columns))
+	for i, c := range columns {
+		insertVals[i] = "src." + d.QuoteIdent(c)
+	}
+
+	if mode == ModeInsert {
+		return fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", table, insertCols, strings.Join(placeholders, ", ")), nil
+	}
+
+	if len(conflictColumns) == 0 {
+		return "", fmt.Errorf("gormbulkups: conflict columns are required to build a MERGE statement on %s", d.Name())
+	}
+
+	merge := fmt.Sprintf(
+		"MERGE INTO %s AS tgt USING (VALUES %s) AS src (%s) ON %s",
+		table, strings.Join(placeholders, ", "), insertCols, mergeJoinCondition(d, conflictColumns),
+	)
+
+	if mode == ModeUpsert && len(updateColumns) > 0 {
+		sets := make([]string, len(updateColumns))
+		for i, c := range updateColumns {
+			q := d.QuoteIdent(c)
+			sets[i] = fmt.Sprintf("tgt.%s = src.%s", q, q)
+		}
+		merge += " WHEN MATCHED THEN UPDATE SET " + strings.Join(sets, ", ")
+	}
+
+	merge += fmt.Sprintf(" WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s);", insertCols, strings.Join(insertVals, ", "))
+	return merge, nil
+}
+
+func (d *sqlserverDialect) Savepoint(name string) (save, rollback, release string) {
+	// T-SQL has no RELEASE SAVEPOINT equivalent; a SAVE TRANSACTION mark is
+	// simply left in place and is superseded by the next one.
+	return fmt.Sprintf("SAVE TRANSACTION %s", name),
+		fmt.Sprintf("ROLLBACK TRANSACTION %s", name),
+		""
+}
+
+func mergeJoinCondition(d *sqlserverDialect, conflictColumns []string) string {
+	conds := make([]string, len(conflictColumns))
+	for i, c := range conflictColumns {
+		q := d.QuoteIdent(c)
+		conds[i] = fmt.Sprintf("tgt.%s = src.%s", q, q)
+	}
+	return strings.Join(conds, " AND ")
+}
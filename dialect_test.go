@@ -0,0 +1,188 @@
+package gormbulkups
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMySQLDialectBuildUpsert(t *testing.T) {
+	d := &mysqlDialect{}
+	table := "`users`"
+	columns := []string{"id", "name"}
+	placeholders := []string{"(?, ?)"}
+
+	cases := []struct {
+		name     string
+		mode     Mode
+		update   []string
+		conflict []string
+		want     string
+	}{
+		{"insert", ModeInsert, nil, nil, "INSERT INTO `users` (`id`, `name`) VALUES (?, ?)"},
+		{"insert ignore", ModeInsertIgnore, nil, nil, "INSERT IGNORE INTO `users` (`id`, `name`) VALUES (?, ?)"},
+		{"upsert", ModeUpsert, []string{"name"}, nil, "INSERT INTO `users` (`id`, `name`) VALUES (?, ?) ON DUPLICATE KEY UPDATE `name`=VALUES(`name`)"},
+		{"upsert do nothing", ModeUpsertDoNothing, nil, []string{"id"}, "INSERT INTO `users` (`id`, `name`) VALUES (?, ?) ON DUPLICATE KEY UPDATE `id`=`id`"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := d.BuildUpsert(table, columns, placeholders, tc.mode, tc.update, tc.conflict, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMySQLDialectReturnColumnsUnsupported(t *testing.T) {
+	d := &mysqlDialect{}
+	if _, err := d.BuildUpsert("`users`", []string{"id"}, []string{"(?)"}, ModeInsert, nil, nil, []string{"id"}); err == nil {
+		t.Fatal("expected error when ReturnColumns is set on MySQL")
+	}
+}
+
+func TestDialectNames(t *testing.T) {
+	cases := []struct {
+		d    Dialect
+		want string
+	}{
+		{&mysqlDialect{}, "mysql"},
+		{&postgresDialect{}, "postgres"},
+		{&sqliteDialect{}, "sqlite3"},
+		{&sqlserverDialect{}, "mssql"},
+	}
+	for _, tc := range cases {
+		if got := tc.d.Name(); got != tc.want {
+			t.Errorf("got %q, want %q", got, tc.want)
+		}
+	}
+}
+
+func TestPostgresDialectBuildUpsert(t *testing.T) {
+	d := &postgresDialect{}
+	table := `"users"`
+	columns := []string{"id", "name"}
+	placeholders := []string{"($1, $2)"}
+
+	cases := []struct {
+		name     string
+		mode     Mode
+		update   []string
+		conflict []string
+		want     string
+		wantErr  bool
+	}{
+		{"insert", ModeInsert, nil, nil, `INSERT INTO "users" ("id", "name") VALUES ($1, $2)`, false},
+		{"insert ignore", ModeInsertIgnore, nil, nil, `INSERT INTO "users" ("id", "name") VALUES ($1, $2) ON CONFLICT DO NOTHING`, false},
+		{"upsert", ModeUpsert, []string{"name"}, []string{"id"}, `INSERT INTO "users" ("id", "name") VALUES ($1, $2) ON CONFLICT ("id") DO UPDATE SET "name"=EXCLUDED."name"`, false},
+		{"upsert missing conflict columns", ModeUpsert, []string{"name"}, nil, "", true},
+		{"upsert do nothing", ModeUpsertDoNothing, nil, []string{"id"}, `INSERT INTO "users" ("id", "name") VALUES ($1, $2) ON CONFLICT ("id") DO NOTHING`, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := d.BuildUpsert(table, columns, placeholders, tc.mode, tc.update, tc.conflict, nil)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPostgresDialectReturning(t *testing.T) {
+	d := &postgresDialect{}
+	got, err := d.BuildUpsert(`"users"`, []string{"id"}, []string{"($1)"}, ModeInsert, nil, nil, []string{"id"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `INSERT INTO "users" ("id") VALUES ($1) RETURNING "id"`
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSQLiteDialectPlaceholderAndSyntax(t *testing.T) {
+	d := &sqliteDialect{}
+	if got := d.Placeholder(0); got != "?" {
+		t.Errorf("got %q, want ?", got)
+	}
+
+	got, err := d.BuildUpsert(`"items"`, []string{"id", "qty"}, []string{"(?, ?)"}, ModeUpsert, []string{"qty"}, []string{"id"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `INSERT INTO "items" ("id", "qty") VALUES (?, ?) ON CONFLICT ("id") DO UPDATE SET "qty"=EXCLUDED."qty"`
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSQLServerDialectBuildUpsert(t *testing.T) {
+	d := &sqlserverDialect{}
+	table := "[users]"
+	columns := []string{"id", "name"}
+	placeholders := []string{"(?, ?)"}
+
+	got, err := d.BuildUpsert(table, columns, placeholders, ModeUpsert, []string{"name"}, []string{"id"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "MERGE INTO [users] AS tgt USING (VALUES (?, ?)) AS src ([id], [name]) ON tgt.[id] = src.[id] WHEN MATCHED THEN UPDATE SET tgt.[name] = src.[name] WHEN NOT MATCHED THEN INSERT ([id], [name]) VALUES (src.[id], src.[name]);"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSQLServerDialectInsertHasNoMerge(t *testing.T) {
+	d := &sqlserverDialect{}
+	got, err := d.BuildUpsert("[users]", []string{"id"}, []string{"(?)"}, ModeInsert, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "INSERT INTO [users] ([id]) VALUES (?)"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSQLServerDialectErrorsNameTheDialect(t *testing.T) {
+	d := &sqlserverDialect{}
+
+	_, err := d.BuildUpsert("[users]", []string{"id"}, []string{"(?)"}, ModeInsert, nil, nil, []string{"id"})
+	if err == nil || !strings.Contains(err.Error(), d.Name()) {
+		t.Fatalf("expected ReturnColumns error to mention %q, got %v", d.Name(), err)
+	}
+
+	_, err = d.BuildUpsert("[users]", []string{"id"}, []string{"(?)"}, ModeUpsert, []string{"id"}, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), d.Name()) {
+		t.Fatalf("expected missing-conflict-columns error to mention %q, got %v", d.Name(), err)
+	}
+}
+
+func TestSQLServerDialectSavepoint(t *testing.T) {
+	d := &sqlserverDialect{}
+	save, rollback, release := d.Savepoint("sp0")
+	if save != "SAVE TRANSACTION sp0" || rollback != "ROLLBACK TRANSACTION sp0" || release != "" {
+		t.Errorf("got (%q, %q, %q)", save, rollback, release)
+	}
+}
+
+func TestMySQLDialectSavepoint(t *testing.T) {
+	d := &mysqlDialect{}
+	save, rollback, release := d.Savepoint("sp0")
+	if save != "SAVEPOINT sp0" || rollback != "ROLLBACK TO SAVEPOINT sp0" || release != "RELEASE SAVEPOINT sp0" {
+		t.Errorf("got (%q, %q, %q)", save, rollback, release)
+	}
+}
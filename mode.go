@@ -0,0 +1,23 @@
+package gormbulkups
+
+// Mode selects which flavor of INSERT statement a chunk is built as. All
+// modes share the same chunking, column extraction and variable binding
+// logic in upsertObjSet; only the SQL a Dialect renders differs.
+type Mode int
+
+const (
+	// ModeInsert performs a plain INSERT with no conflict handling. A
+	// conflicting row causes the statement to fail, same as a bare SQL
+	// INSERT.
+	ModeInsert Mode = iota
+	// ModeInsertIgnore inserts rows and silently skips any that conflict
+	// with an existing row, leaving the existing row untouched.
+	ModeInsertIgnore
+	// ModeUpsert inserts rows and updates the existing row's columns on
+	// conflict. This is the original behavior of BulkUpsert.
+	ModeUpsert
+	// ModeUpsertDoNothing inserts rows and leaves the existing row
+	// untouched on conflict. Unlike ModeInsertIgnore this always targets
+	// the conflict/unique columns explicitly where the dialect requires it.
+	ModeUpsertDoNothing
+)
@@ -0,0 +1,119 @@
+package gormbulkups
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// fieldKind distinguishes the three ways a column's value can be derived
+// from an object, mirroring the branches extractMapValue used to evaluate
+// on every single object.
+type fieldKind int
+
+const (
+	fieldNormal fieldKind = iota
+	fieldTimestamp
+	fieldDefault
+)
+
+// fieldPlan is the memoized, per-struct-field recipe for pulling one
+// column's value out of any object of the plan's type: a struct field index
+// path plus the CreatedAt/UpdatedAt/DEFAULT-tag handling that used to be
+// re-derived via gorm.Scope for every object in the batch.
+type fieldPlan struct {
+	dbName        string
+	index         []int
+	kind          fieldKind
+	hasDefaultTag bool
+	defaultValue  interface{}
+}
+
+// extract reads this plan's column out of v, the reflect.Value of an object
+// of the plan's type.
+func (p *fieldPlan) extract(v reflect.Value) interface{} {
+	field := v.FieldByIndex(p.index)
+	switch p.kind {
+	case fieldTimestamp:
+		return time.Now()
+	case fieldDefault:
+		if field.IsZero() && p.hasDefaultTag {
+			return p.defaultValue
+		}
+		return field.Interface()
+	default:
+		return field.Interface()
+	}
+}
+
+// typeSchema is the memoized result of walking a sample object's
+// gorm.Scope once: the sorted column list and a fieldPlan per column, so a
+// batch of N objects of the same type reflects over its fields only once
+// instead of N times.
+type typeSchema struct {
+	columns []string
+	fields  []fieldPlan
+}
+
+type schemaCacheKey struct {
+	typ     reflect.Type
+	exclude string
+}
+
+var schemaCache sync.Map // map[schemaCacheKey]*typeSchema
+
+// schemaFor resolves (and memoizes) the typeSchema for sample's type,
+// honoring excludeColumns the same way extractMapValue used to.
+func schemaFor(sample interface{}, excludeColumns []string) *typeSchema {
+	key := schemaCacheKey{typ: reflect.TypeOf(sample), exclude: strings.Join(excludeColumns, ",")}
+	if cached, ok := schemaCache.Load(key); ok {
+		return cached.(*typeSchema)
+	}
+
+	schema := buildSchema(sample, excludeColumns)
+	actual, _ := schemaCache.LoadOrStore(key, schema)
+	return actual.(*typeSchema)
+}
+
+// buildSchema walks sample's gorm.Scope once and compiles a fieldPlan per
+// eligible column, sorted by database column name like sortedKeys did.
+func buildSchema(sample interface{}, excludeColumns []string) *typeSchema {
+	scope := &gorm.Scope{Value: sample}
+
+	plans := make([]fieldPlan, 0, len(scope.Fields()))
+	for _, field := range scope.Fields() {
+		_, hasForeignKey := field.TagSettingsGet("FOREIGNKEY")
+		if containString(excludeColumns, field.Struct.Name) ||
+			field.StructField.Relationship != nil ||
+			hasForeignKey ||
+			field.IsIgnored {
+			continue
+		}
+
+		plan := fieldPlan{dbName: field.DBName, index: field.Struct.Index}
+		switch {
+		case field.Struct.Name == "CreatedAt" || field.Struct.Name == "UpdatedAt":
+			plan.kind = fieldTimestamp
+		case field.StructField.HasDefaultValue:
+			plan.kind = fieldDefault
+			if val, ok := field.TagSettingsGet("DEFAULT"); ok {
+				plan.hasDefaultTag = true
+				plan.defaultValue = val
+			}
+		}
+		plans = append(plans, plan)
+	}
+
+	sort.Slice(plans, func(i, j int) bool { return plans[i].dbName < plans[j].dbName })
+
+	columns := make([]string, len(plans))
+	for i, p := range plans {
+		columns[i] = gorm.ToColumnName(p.dbName)
+	}
+
+	return &typeSchema{columns: columns, fields: plans}
+}
@@ -0,0 +1,96 @@
+package gormbulkups
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type schemaTestRecord struct {
+	ID        uint `gorm:"primary_key"`
+	Name      string
+	Score     int `gorm:"default:10"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func TestBuildSchemaColumnsSortedAndExcluded(t *testing.T) {
+	schema := buildSchema(schemaTestRecord{}, nil)
+
+	want := []string{"created_at", "id", "name", "score", "updated_at"}
+	if len(schema.columns) != len(want) {
+		t.Fatalf("got %d columns %v, want %d: %v", len(schema.columns), schema.columns, len(want), want)
+	}
+	for i, col := range want {
+		if schema.columns[i] != col {
+			t.Errorf("column %d: got %q, want %q", i, schema.columns[i], col)
+		}
+	}
+
+	excluded := buildSchema(schemaTestRecord{}, []string{"Name"})
+	for _, col := range excluded.columns {
+		if col == "name" {
+			t.Error("expected Name to be excluded from the schema")
+		}
+	}
+}
+
+func TestSchemaForCachesByTypeAndExclude(t *testing.T) {
+	s1 := schemaFor(schemaTestRecord{}, nil)
+	s2 := schemaFor(schemaTestRecord{}, nil)
+	if s1 != s2 {
+		t.Error("expected schemaFor to return the cached schema for identical args")
+	}
+
+	s3 := schemaFor(schemaTestRecord{}, []string{"Name"})
+	if s3 == s1 {
+		t.Error("expected a distinct schema once excludeColumns differs")
+	}
+}
+
+func fieldPlanFor(t *testing.T, schema *typeSchema, dbName string) *fieldPlan {
+	t.Helper()
+	for i := range schema.fields {
+		if schema.fields[i].dbName == dbName {
+			return &schema.fields[i]
+		}
+	}
+	t.Fatalf("no fieldPlan for column %q", dbName)
+	return nil
+}
+
+func TestFieldPlanExtractTimestamp(t *testing.T) {
+	schema := buildSchema(schemaTestRecord{}, nil)
+	plan := fieldPlanFor(t, schema, "created_at")
+
+	v := reflect.ValueOf(schemaTestRecord{})
+	got, ok := plan.extract(v).(time.Time)
+	if !ok {
+		t.Fatalf("expected time.Time, got %T", plan.extract(v))
+	}
+	if time.Since(got) > time.Second {
+		t.Errorf("expected extract to stamp the current time, got %v", got)
+	}
+}
+
+func TestFieldPlanExtractDefaultValueOnZero(t *testing.T) {
+	schema := buildSchema(schemaTestRecord{}, nil)
+	plan := fieldPlanFor(t, schema, "score")
+
+	v := reflect.ValueOf(schemaTestRecord{})
+	got := plan.extract(v)
+	if got != "10" {
+		t.Errorf("got %v, want the DEFAULT tag value %q", got, "10")
+	}
+}
+
+func TestFieldPlanExtractNonZeroSkipsDefault(t *testing.T) {
+	schema := buildSchema(schemaTestRecord{}, nil)
+	plan := fieldPlanFor(t, schema, "score")
+
+	v := reflect.ValueOf(schemaTestRecord{Score: 42})
+	got := plan.extract(v)
+	if got != 42 {
+		t.Errorf("got %v, want 42", got)
+	}
+}
@@ -0,0 +1,27 @@
+package gormbulkups
+
+// splitObjects splits objects into chunks of at most chunkSize, preserving
+// order, so a batch larger than the driver's parameter limit can be
+// executed as several statements. A non-positive chunkSize returns all
+// objects as a single chunk.
+func splitObjects(objects []interface{}, chunkSize int) [][]interface{} {
+	if chunkSize <= 0 || len(objects) <= chunkSize {
+		return [][]interface{}{objects}
+	}
+
+	chunks := make([][]interface{}, 0, (len(objects)+chunkSize-1)/chunkSize)
+	for chunkSize < len(objects) {
+		objects, chunks = objects[chunkSize:], append(chunks, objects[0:chunkSize:chunkSize])
+	}
+	return append(chunks, objects)
+}
+
+// containString reports whether s is present in list.
+func containString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}